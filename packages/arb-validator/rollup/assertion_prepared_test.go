@@ -0,0 +1,71 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollup"
+)
+
+// fixedLeaderElector always elects leader, regardless of the candidate pool
+// it's passed, so a test can force a specific (possibly non-proof-owning)
+// staker to be the one submitIfLeader hands to its submit callback.
+type fixedLeaderElector struct {
+	leader common.Address
+}
+
+func (e *fixedLeaderElector) Leader(context.Context, *big.Int, [32]byte, []common.Address) (common.Address, error) {
+	return e.leader, nil
+}
+
+func (e *fixedLeaderElector) FallbackTicks() uint64 { return 0 }
+
+// TestAssertionPreparedSubmitsUnderProofOwnersCredentials covers the bug
+// fixed in AssertionPrepared where the elected leader passed into
+// submitIfLeader's callback could differ from the staker whose path proof
+// was actually generated: only the proof owner's credentials sign a valid
+// assertion, so AssertionPrepared must always submit via that staker,
+// independent of which staker submitIfLeader elects.
+func TestAssertionPreparedSubmitsUnderProofOwnersCredentials(t *testing.T) {
+	elector := &fixedLeaderElector{}
+	sc := NewSimChainWithElector(t, 2, elector)
+	defer sc.Close()
+
+	asserter, other := sc.Stakers[0], sc.Stakers[1]
+	// Elect "other" as leader even though only asserter has a path proof
+	// registered below: before the fix, AssertionPrepared would submit
+	// asserter's proof under the elected "other" staker's credentials,
+	// which would revert on chain.
+	elector.leader = other.From
+
+	leafHash := [32]byte{7, 7, 7}
+	prepared := rollup.NewAssertionPreparedForTest(sc.Chain, leafHash, asserter.From)
+	sc.Listener.AssertionPrepared(prepared)
+
+	if got := sc.awaitSubmittedTxCountFor(asserter.From, 1, time.Second); got != 1 {
+		t.Fatalf("expected the proof-owning staker %s to submit exactly one assertion, got %d", asserter.From.Hex(), got)
+	}
+	if got := sc.submittedTxCountFor(other.From); got != 0 {
+		t.Fatalf("expected the elected-but-proof-less staker %s to submit nothing, got %d", other.From.Hex(), got)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/structures"
+)
+
+// The constructors below exist only so this package's own external tests
+// (package rollup_test) can build the unexported values ChainListener's
+// methods take. Being a _test.go file, none of this compiles into the
+// rollup package that non-test code imports. Production code should always
+// derive these from chain/nodeGraph state instead of constructing them
+// directly.
+
+// NewConflictNodeForTest builds a *Node for StartedChallenge's defend/
+// challenge dispatch. linkType selects which branch runs; numSteps
+// populates disputable.AssertionParams.NumSteps, which the
+// InvalidExecutionChildType branch reads alongside linkType, so that branch
+// doesn't dereference a nil disputable.
+func NewConflictNodeForTest(linkType structures.ChildType, numSteps uint64) *Node {
+	return &Node{
+		linkType: linkType,
+		disputable: &structures.Disputable{
+			AssertionParams: structures.AssertionParams{NumSteps: numSteps},
+		},
+	}
+}
+
+// NewPruneParamsForTest builds the []pruneParams PrunableLeafs expects,
+// repeating leafHash count times so a test can exercise its dedup logic.
+func NewPruneParamsForTest(leafHash [32]byte, count int) []pruneParams {
+	params := make([]pruneParams, count)
+	for i := range params {
+		params[i] = pruneParams{leafHash: leafHash}
+	}
+	return params
+}
+
+// NewAssertionPreparedForTest registers leaf as the node at leafHash and
+// staker as holding a trivial (self-to-self) path proof to it, directly on
+// chain's nodeGraph, then returns a *preparedAssertion for leafHash. A real
+// ChainObserver derives this state by watching on-chain events; this
+// shortcuts straight to the end state so a test can drive AssertionPrepared
+// for a specific staker without standing up that whole flow.
+func NewAssertionPreparedForTest(chain *ChainObserver, leafHash [32]byte, staker common.Address) *preparedAssertion {
+	leaf := &Node{}
+	chain.nodeGraph.nodeFromHash[leafHash] = leaf
+	chain.nodeGraph.stakers.Add(staker, &stakerPosition{location: leaf})
+	return &preparedAssertion{leafHash: leafHash}
+}
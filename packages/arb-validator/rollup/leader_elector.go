@@ -0,0 +1,137 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrNoStakers is returned by a LeaderElector when asked to choose among an
+// empty staker set.
+var ErrNoStakers = errors.New("no stakers to elect a leader from")
+
+// ErrInvalidEpochLength is returned by DrandLeaderElector.Leader when its
+// epochLength is zero, which would otherwise divide by zero deriving the
+// drand round.
+var ErrInvalidEpochLength = errors.New("epochLength must be greater than zero")
+
+// LeaderElector deterministically picks which staker should be responsible
+// for sending a given on-chain transaction, so that honest validator
+// processes that agree on the same inputs don't race each other and waste
+// gas resubmitting the same assertion, confirmation, or prune.
+type LeaderElector interface {
+	// Leader returns the address that should submit the transaction
+	// associated with nodeHash for the L1 block window containing
+	// blockNum. Every listener that calls Leader with the same arguments
+	// and the same staker set must agree on the result.
+	Leader(ctx context.Context, blockNum *big.Int, nodeHash [32]byte, stakers []common.Address) (common.Address, error)
+
+	// FallbackTicks is how many L1 blocks a non-leader should wait for the
+	// elected leader's transaction to land before submitting itself.
+	FallbackTicks() uint64
+}
+
+// NoopElector preserves the pre-election behavior: it elects the first staker
+// in the set and has zero fallback ticks, so a listener that is not elected
+// still submits as soon as its zero-length fallback fires. Every listener
+// therefore submits, and the existing per-process dedup maps are the only
+// thing preventing a duplicate submission.
+type NoopElector struct{}
+
+func (NoopElector) Leader(_ context.Context, _ *big.Int, _ [32]byte, stakers []common.Address) (common.Address, error) {
+	if len(stakers) == 0 {
+		return common.Address{}, ErrNoStakers
+	}
+	return stakers[0], nil
+}
+
+func (NoopElector) FallbackTicks() uint64 {
+	return 0
+}
+
+// BeaconEntry is a single round of randomness published by a drand beacon.
+type BeaconEntry struct {
+	Round uint64
+	Data  []byte
+}
+
+// BeaconAPI is the subset of Dione's beacon client that DrandLeaderElector
+// depends on.
+type BeaconAPI interface {
+	Entry(ctx context.Context, round uint64) (BeaconEntry, error)
+}
+
+// DrandLeaderElector elects a leader by hashing a drand beacon round together
+// with the node hash under contention, then indexing into the sorted set of
+// active stakers. Because the beacon entry for a given round is the same for
+// every honest party, and the staker set and node hash are derived from
+// on-chain state every listener has already observed, all listeners that
+// call Leader for the same round converge on the same address.
+type DrandLeaderElector struct {
+	beacon        BeaconAPI
+	epochLength   uint64
+	fallbackTicks uint64
+}
+
+// NewDrandLeaderElector builds a DrandLeaderElector that rotates leadership
+// every epochLength L1 blocks, and gives a non-leader fallbackTicks blocks to
+// wait before submitting after the elected leader.
+func NewDrandLeaderElector(beacon BeaconAPI, epochLength uint64, fallbackTicks uint64) *DrandLeaderElector {
+	return &DrandLeaderElector{
+		beacon:        beacon,
+		epochLength:   epochLength,
+		fallbackTicks: fallbackTicks,
+	}
+}
+
+func (e *DrandLeaderElector) Leader(ctx context.Context, blockNum *big.Int, nodeHash [32]byte, stakers []common.Address) (common.Address, error) {
+	if len(stakers) == 0 {
+		return common.Address{}, ErrNoStakers
+	}
+	if e.epochLength == 0 {
+		return common.Address{}, ErrInvalidEpochLength
+	}
+	round := new(big.Int).Div(blockNum, new(big.Int).SetUint64(e.epochLength)).Uint64()
+	entry, err := e.beacon.Entry(ctx, round)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	sorted := make([]common.Address, len(stakers))
+	copy(sorted, stakers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Bytes(), sorted[j].Bytes()) < 0
+	})
+
+	h := sha256.New()
+	h.Write(entry.Data)
+	h.Write(nodeHash[:])
+	digest := new(big.Int).SetBytes(h.Sum(nil))
+	idx := new(big.Int).Mod(digest, big.NewInt(int64(len(sorted)))).Int64()
+	return sorted[idx], nil
+}
+
+func (e *DrandLeaderElector) FallbackTicks() uint64 {
+	return e.fallbackTicks
+}
@@ -0,0 +1,85 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type fixedBeacon struct {
+	entry BeaconEntry
+}
+
+func (b fixedBeacon) Entry(context.Context, uint64) (BeaconEntry, error) {
+	return b.entry, nil
+}
+
+// TestDrandLeaderElectorExactlyOneSubmits simulates N listeners that all
+// agree on the same beacon output and staker set, and verifies that
+// exactly one of them is elected leader for a given nodeHash.
+func TestDrandLeaderElectorExactlyOneSubmits(t *testing.T) {
+	beacon := fixedBeacon{entry: BeaconEntry{Round: 7, Data: []byte("drand-round-7")}}
+	elector := NewDrandLeaderElector(beacon, 10, 4)
+
+	stakers := []common.Address{
+		common.HexToAddress("0x1"),
+		common.HexToAddress("0x2"),
+		common.HexToAddress("0x3"),
+		common.HexToAddress("0x4"),
+		common.HexToAddress("0x5"),
+	}
+	blockNum := big.NewInt(73)
+	nodeHash := [32]byte{1, 2, 3}
+
+	submitted := 0
+	for _, me := range stakers {
+		leader, err := elector.Leader(context.Background(), blockNum, nodeHash, stakers)
+		if err != nil {
+			t.Fatalf("unexpected error electing leader: %v", err)
+		}
+		if leader == me {
+			submitted++
+		}
+	}
+
+	if submitted != 1 {
+		t.Fatalf("expected exactly one staker to be elected leader, got %d", submitted)
+	}
+}
+
+func TestDrandLeaderElectorNoStakers(t *testing.T) {
+	beacon := fixedBeacon{entry: BeaconEntry{Round: 1, Data: []byte("x")}}
+	elector := NewDrandLeaderElector(beacon, 10, 4)
+	_, err := elector.Leader(context.Background(), big.NewInt(1), [32]byte{}, nil)
+	if err != ErrNoStakers {
+		t.Fatalf("expected ErrNoStakers, got %v", err)
+	}
+}
+
+func TestDrandLeaderElectorZeroEpochLength(t *testing.T) {
+	beacon := fixedBeacon{entry: BeaconEntry{Round: 1, Data: []byte("x")}}
+	elector := NewDrandLeaderElector(beacon, 0, 4)
+	stakers := []common.Address{common.HexToAddress("0x1")}
+	_, err := elector.Leader(context.Background(), big.NewInt(1), [32]byte{}, stakers)
+	if err != ErrInvalidEpochLength {
+		t.Fatalf("expected ErrInvalidEpochLength, got %v", err)
+	}
+}
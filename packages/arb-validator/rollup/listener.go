@@ -17,8 +17,13 @@
 package rollup
 
 import (
+	"bytes"
 	"context"
 	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/arb"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/arbbridge"
@@ -30,9 +35,15 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/offchainlabs/arbitrum/packages/arb-validator/structures"
 )
 
+// averageL1BlockTime approximates L1 block production so a non-leader can
+// wait roughly fallbackTicks blocks before assuming the elected leader has
+// stalled and submitting itself.
+const averageL1BlockTime = 15 * time.Second
+
 type ChainListener interface {
 	StakeCreated(arbbridge.StakeCreatedEvent)
 	StakeRemoved(arbbridge.StakeRefundedEvent)
@@ -55,23 +66,134 @@ type ChainListener interface {
 }
 
 type ValidatorChainListener struct {
-	chain                  *ChainObserver
+	chain   *ChainObserver
+	elector LeaderElector
+	txmgr   *TxManager
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu                     sync.Mutex
 	stakers                map[common.Address]*StakerListener
+	stakerAuths            map[common.Address]*bind.TransactOpts
 	broadcastAssertions    map[[32]byte]bool
 	broadcastConfirmations map[[32]byte]bool
 	broadcastLeafPrunes    map[[32]byte]bool
+
+	// currentBlockNum is the most recently observed L1 block number, used
+	// to derive the drand round for leader election. It is updated by
+	// UpdateBlockNum as new L1 blocks are observed.
+	currentBlockNum *big.Int
 }
 
+// NewValidatorChainListener builds a listener whose goroutines and enqueued
+// transactions are all tied to ctx: cancelling ctx, or calling the returned
+// listener's Close, stops outstanding work instead of leaving it to wedge on
+// a stuck RPC.
 func NewValidatorChainListener(
+	ctx context.Context,
 	chain *ChainObserver,
+	elector LeaderElector,
+	txmgr *TxManager,
 ) *ValidatorChainListener {
+	lisCtx, cancel := context.WithCancel(ctx)
 	return &ValidatorChainListener{
 		chain:                  chain,
+		elector:                elector,
+		txmgr:                  txmgr,
+		ctx:                    lisCtx,
+		cancel:                 cancel,
 		stakers:                make(map[common.Address]*StakerListener),
+		stakerAuths:            make(map[common.Address]*bind.TransactOpts),
 		broadcastAssertions:    make(map[[32]byte]bool),
 		broadcastConfirmations: make(map[[32]byte]bool),
 		broadcastLeafPrunes:    make(map[[32]byte]bool),
+		currentBlockNum:        big.NewInt(0),
+	}
+}
+
+// Close cancels the listener's context, stops its TxManager from accepting
+// new work, and causes any submitIfLeader fallback timers and in-flight
+// sends to wind down.
+func (lis *ValidatorChainListener) Close() {
+	lis.cancel()
+	lis.txmgr.Close()
+}
+
+// UpdateBlockNum records the latest L1 block number the caller has observed,
+// so subsequent leader elections derive their drand round from it.
+func (lis *ValidatorChainListener) UpdateBlockNum(blockNum *big.Int) {
+	lis.mu.Lock()
+	defer lis.mu.Unlock()
+	lis.currentBlockNum = blockNum
+}
+
+func (lis *ValidatorChainListener) blockNum() *big.Int {
+	lis.mu.Lock()
+	defer lis.mu.Unlock()
+	return lis.currentBlockNum
+}
+
+// sortedStakerAddresses returns the addresses of every staker active on
+// chain, in a deterministic order, suitable for indexing by LeaderElector.
+// This must be the global on-chain set rather than lis.stakers (the subset
+// this process happens to manage locally): each validator process only ever
+// AddStakers its own key, so electing over the local set alone would just
+// have every process elect itself, defeating the point of leader election.
+func (lis *ValidatorChainListener) sortedStakerAddresses() []common.Address {
+	addrs := lis.chain.nodeGraph.stakers.Addresses()
+	sorted := make([]common.Address, len(addrs))
+	copy(sorted, addrs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Bytes(), sorted[j].Bytes()) < 0
+	})
+	return sorted
+}
+
+// submitIfLeader elects a leader for nodeHash over every staker active on
+// chain. If the elected address is one this process manages locally, submit
+// runs immediately; otherwise it is deferred for fallbackTicks times
+// averageL1BlockTime, an estimate of fallbackTicks L1 blocks, giving the
+// elected leader a chance to land its transaction first. done reports whether an on-chain event has already satisfied this
+// submission, in which case the fallback is skipped.
+func (lis *ValidatorChainListener) submitIfLeader(nodeHash [32]byte, submit func(*StakerListener), done func() bool) {
+	leader, err := lis.elector.Leader(lis.ctx, lis.blockNum(), nodeHash, lis.sortedStakerAddresses())
+	if err != nil {
+		log.Println("Error electing leader", err)
+		return
+	}
+	if staker, ok := lis.stakers[leader]; ok {
+		submit(staker)
+		return
 	}
+
+	fallback := time.Duration(lis.elector.FallbackTicks()) * averageL1BlockTime
+	time.AfterFunc(fallback, func() {
+		if lis.ctx.Err() != nil || done() {
+			return
+		}
+		for _, staker := range lis.stakers {
+			submit(staker)
+			break
+		}
+	})
+}
+
+// alreadyBroadcast reports whether hash is already marked in tracker.
+func (lis *ValidatorChainListener) alreadyBroadcast(tracker map[[32]byte]bool, hash [32]byte) bool {
+	lis.mu.Lock()
+	defer lis.mu.Unlock()
+	return tracker[hash]
+}
+
+// markBroadcast marks hash as sent in tracker, returning whether it was
+// already marked beforehand.
+func (lis *ValidatorChainListener) markBroadcast(tracker map[[32]byte]bool, hash [32]byte) bool {
+	lis.mu.Lock()
+	defer lis.mu.Unlock()
+	alreadySent := tracker[hash]
+	tracker[hash] = true
+	return alreadySent
 }
 
 func (lis *ValidatorChainListener) AddStaker(client *ethclient.Client, auth *bind.TransactOpts) error {
@@ -82,7 +204,13 @@ func (lis *ValidatorChainListener) AddStaker(client *ethclient.Client, auth *bin
 	location := lis.chain.knownValidNode
 	proof1 := GeneratePathProof(lis.chain.nodeGraph.latestConfirmed, location)
 	proof2 := GeneratePathProof(location, lis.chain.nodeGraph.getLeaf(location))
-	go contract.PlaceStake(context.TODO(), lis.chain.nodeGraph.params.StakeRequirement, proof1, proof2)
+	stakeRequirement := lis.chain.nodeGraph.params.StakeRequirement
+	lis.txmgr.Enqueue(lis.ctx, auth, TxRequest{
+		Kind: "PlaceStake",
+		Send: func(ctx context.Context, opts *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.PlaceStake(ctx, stakeRequirement, proof1, proof2)
+		},
+	})
 	address := auth.From
 	staker := &StakerListener{
 		myAddr:   address,
@@ -90,6 +218,7 @@ func (lis *ValidatorChainListener) AddStaker(client *ethclient.Client, auth *bin
 		contract: contract,
 	}
 	lis.stakers[address] = staker
+	lis.stakerAuths[address] = auth
 	return nil
 }
 
@@ -98,10 +227,10 @@ func (lis *ValidatorChainListener) StakeCreated(ev arbbridge.StakeCreatedEvent)
 	if ok {
 		opps := lis.chain.nodeGraph.checkChallengeOpportunityAllPairs()
 		for _, opp := range opps {
-			go staker.initiateChallenge(context.TODO(), opp)
+			go staker.initiateChallenge(lis.ctx, opp)
 		}
 	} else {
-		lis.challengeStakerIfPossible(context.TODO(), ev.Staker)
+		lis.challengeStakerIfPossible(lis.ctx, ev.Staker)
 	}
 }
 
@@ -110,7 +239,7 @@ func (lis *ValidatorChainListener) StakeRemoved(arbbridge.StakeRefundedEvent) {
 }
 
 func (lis *ValidatorChainListener) StakeMoved(ev arbbridge.StakeMovedEvent) {
-	lis.challengeStakerIfPossible(context.TODO(), ev.Staker)
+	lis.challengeStakerIfPossible(lis.ctx, ev.Staker)
 }
 
 func (lis *ValidatorChainListener) challengeStakerIfPossible(ctx context.Context, stakerAddr common.Address) {
@@ -135,37 +264,66 @@ func (lis *ValidatorChainListener) challengeStakerIfPossible(ctx context.Context
 	}
 }
 
+// StartedChallenge dispatches the asserter's defense and the challenger's
+// attack for a freshly opened challenge. Both sides run through
+// lis.txmgr.Enqueue, tied to lis.ctx: a stuck RPC here must not wedge the
+// listener, since a challenge runs against a ticking on-chain deadline.
 func (lis *ValidatorChainListener) StartedChallenge(ev arbbridge.ChallengeStartedEvent, conflictNode *Node, challengerAncestor *Node) {
-	asserter, ok := lis.stakers[ev.Asserter]
-	if ok {
+	if asserter, ok := lis.stakers[ev.Asserter]; ok {
 		switch conflictNode.linkType {
 		case structures.InvalidPendingChildType:
-			go asserter.defendPendingTop(ev.ChallengeContract, lis.chain.pendingInbox, conflictNode)
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[asserter.myAddr], TxRequest{
+				Kind: "DefendPendingTop",
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return asserter.defendPendingTop(ctx, ev.ChallengeContract, lis.chain.pendingInbox, conflictNode)
+				},
+			})
 		case structures.InvalidMessagesChildType:
-			go asserter.defendMessages(ev.ChallengeContract, lis.chain.pendingInbox, conflictNode)
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[asserter.myAddr], TxRequest{
+				Kind: "DefendMessages",
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return asserter.defendMessages(ctx, ev.ChallengeContract, lis.chain.pendingInbox, conflictNode)
+				},
+			})
 		case structures.InvalidExecutionChildType:
-			go asserter.defendExecution(
-				ev.ChallengeContract,
-				conflictNode.machine,
-				lis.chain.ExecutionPrecondition(conflictNode),
-				conflictNode.disputable.AssertionParams.NumSteps,
-			)
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[asserter.myAddr], TxRequest{
+				Kind: "DefendExecution",
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return asserter.defendExecution(
+						ctx,
+						ev.ChallengeContract,
+						conflictNode.machine,
+						lis.chain.ExecutionPrecondition(conflictNode),
+						conflictNode.disputable.AssertionParams.NumSteps,
+					)
+				},
+			})
 		}
 	}
 
-	challenger, ok := lis.stakers[ev.Challenger]
-	if ok {
+	if challenger, ok := lis.stakers[ev.Challenger]; ok {
 		switch conflictNode.linkType {
 		case structures.InvalidPendingChildType:
-			go challenger.challengePendingTop(ev.ChallengeContract, lis.chain.pendingInbox)
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[challenger.myAddr], TxRequest{
+				Kind: "ChallengePendingTop",
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return challenger.challengePendingTop(ctx, ev.ChallengeContract, lis.chain.pendingInbox)
+				},
+			})
 		case structures.InvalidMessagesChildType:
-			go challenger.challengeMessages(ev.ChallengeContract, lis.chain.pendingInbox, conflictNode)
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[challenger.myAddr], TxRequest{
+				Kind: "ChallengeMessages",
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return challenger.challengeMessages(ctx, ev.ChallengeContract, lis.chain.pendingInbox, conflictNode)
+				},
+			})
 		case structures.InvalidExecutionChildType:
-			go challenger.challengeExecution(
-				ev.ChallengeContract,
-				conflictNode.machine,
-				lis.chain.ExecutionPrecondition(conflictNode),
-			)
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[challenger.myAddr], TxRequest{
+				Kind: "ChallengeExecution",
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return challenger.challengeExecution(ctx, ev.ChallengeContract, conflictNode.machine, lis.chain.ExecutionPrecondition(conflictNode))
+				},
+			})
 		}
 	}
 }
@@ -180,7 +338,7 @@ func (lis *ValidatorChainListener) CompletedChallenge(ev arbbridge.ChallengeComp
 	if ok {
 		lis.lostChallenge(ev)
 	}
-	lis.challengeStakerIfPossible(context.TODO(), ev.Winner)
+	lis.challengeStakerIfPossible(lis.ctx, ev.Winner)
 }
 
 func (lis *ValidatorChainListener) lostChallenge(arbbridge.ChallengeCompletedEvent) {
@@ -191,135 +349,179 @@ func (lis *ValidatorChainListener) wonChallenge(arbbridge.ChallengeCompletedEven
 
 }
 
-func (lis *ValidatorChainListener) SawAssertion(arbbridge.AssertedEvent, *protocol.TimeBlocks, [32]byte) {
-
+func (lis *ValidatorChainListener) SawAssertion(_ arbbridge.AssertedEvent, _ *protocol.TimeBlocks, leafHash [32]byte) {
+	// An AssertedEvent for this leaf landed, so a non-leader fallback
+	// submission (if any was scheduled) must not fire.
+	lis.mu.Lock()
+	lis.broadcastAssertions[leafHash] = true
+	lis.mu.Unlock()
 }
 
-func (lis *ValidatorChainListener) ConfirmedNode(arbbridge.ConfirmedEvent) {
-
+func (lis *ValidatorChainListener) ConfirmedNode(ev arbbridge.ConfirmedEvent) {
+	lis.mu.Lock()
+	lis.broadcastConfirmations[ev.NodeHash] = true
+	lis.mu.Unlock()
 }
 
-func (lis *ValidatorChainListener) PrunedLeaf(arbbridge.PrunedEvent) {
-
+func (lis *ValidatorChainListener) PrunedLeaf(ev arbbridge.PrunedEvent) {
+	lis.mu.Lock()
+	lis.broadcastLeafPrunes[ev.Leaf] = true
+	lis.mu.Unlock()
 }
 
 func (lis *ValidatorChainListener) AssertionPrepared(prepared *preparedAssertion) {
-	_, alreadySent := lis.broadcastAssertions[prepared.leafHash]
-	if alreadySent {
+	if lis.alreadyBroadcast(lis.broadcastAssertions, prepared.leafHash) {
 		return
 	}
 	leaf, ok := lis.chain.nodeGraph.nodeFromHash[prepared.leafHash]
-	if ok {
-		for _, staker := range lis.stakers {
-			stakerPos := lis.chain.nodeGraph.stakers.Get(staker.myAddr)
-			if stakerPos != nil {
-				proof := GeneratePathProof(stakerPos.location, leaf)
-				if proof != nil {
-					lis.broadcastAssertions[prepared.leafHash] = true
-					go func() {
-						err := staker.makeAssertion(context.TODO(), prepared, proof)
-						if err != nil {
-							log.Println("Error making assertion", err)
-						} else {
-							log.Println("Successfully made assertion")
-						}
-					}()
-
-					break
-				}
-			}
+	if !ok {
+		return
+	}
+	for _, staker := range lis.stakers {
+		stakerPos := lis.chain.nodeGraph.stakers.Get(staker.myAddr)
+		if stakerPos == nil {
+			continue
 		}
+		proof := GeneratePathProof(stakerPos.location, leaf)
+		if proof == nil {
+			continue
+		}
+		// asserter, not submitIfLeader's own *StakerListener argument, is
+		// who must sign: proof is specific to asserter's on-chain staker
+		// position, and submitting it under a different staker's
+		// credentials (the elected leader, or the fallback's arbitrary
+		// pick) would revert.
+		asserter := staker
+		lis.submitIfLeader(
+			prepared.leafHash,
+			func(*StakerListener) {
+				if lis.markBroadcast(lis.broadcastAssertions, prepared.leafHash) {
+					return
+				}
+				go func() {
+					err := asserter.makeAssertion(lis.ctx, prepared, proof)
+					if err != nil {
+						log.Println("Error making assertion", err)
+					} else {
+						log.Println("Successfully made assertion")
+					}
+				}()
+			},
+			func() bool { return lis.alreadyBroadcast(lis.broadcastAssertions, prepared.leafHash) },
+		)
+		break
 	}
 }
 
 func (lis *ValidatorChainListener) ValidNodeConfirmable(conf *confirmValidOpportunity) {
-	_, alreadySent := lis.broadcastConfirmations[conf.nodeHash]
-	if alreadySent {
+	if lis.alreadyBroadcast(lis.broadcastConfirmations, conf.nodeHash) {
 		return
 	}
-	for _, staker := range lis.stakers {
-		lis.broadcastConfirmations[conf.nodeHash] = true
-		go func() {
-			staker.Lock()
-			staker.contract.ConfirmValid(
-				context.TODO(),
-				conf.deadlineTicks,
-				conf.messages,
-				conf.logsAcc,
-				conf.vmProtoStateHash,
-				conf.stakerAddresses,
-				conf.stakerProofs,
-				conf.stakerProofOffsets,
-			)
-			staker.Unlock()
-		}()
-		break
-	}
+	lis.submitIfLeader(
+		conf.nodeHash,
+		func(staker *StakerListener) {
+			if lis.markBroadcast(lis.broadcastConfirmations, conf.nodeHash) {
+				return
+			}
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[staker.myAddr], TxRequest{
+				Kind:     "ConfirmValid",
+				DedupKey: conf.nodeHash,
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return staker.contract.ConfirmValid(
+						ctx,
+						conf.deadlineTicks,
+						conf.messages,
+						conf.logsAcc,
+						conf.vmProtoStateHash,
+						conf.stakerAddresses,
+						conf.stakerProofs,
+						conf.stakerProofOffsets,
+					)
+				},
+			})
+		},
+		func() bool { return lis.alreadyBroadcast(lis.broadcastConfirmations, conf.nodeHash) },
+	)
 }
 
 func (lis *ValidatorChainListener) InvalidNodeConfirmable(conf *confirmInvalidOpportunity) {
-	_, alreadySent := lis.broadcastConfirmations[conf.nodeHash]
-	if alreadySent {
+	if lis.alreadyBroadcast(lis.broadcastConfirmations, conf.nodeHash) {
 		return
 	}
-	for _, staker := range lis.stakers {
-		lis.broadcastConfirmations[conf.nodeHash] = true
-		go func() {
-			staker.Lock()
-			staker.contract.ConfirmInvalid(
-				context.TODO(),
-				conf.deadlineTicks,
-				conf.challengeNodeData,
-				conf.branch,
-				conf.vmProtoStateHash,
-				conf.stakerAddresses,
-				conf.stakerProofs,
-				conf.stakerProofOffsets,
-			)
-			staker.Unlock()
-		}()
-		break
-	}
+	lis.submitIfLeader(
+		conf.nodeHash,
+		func(staker *StakerListener) {
+			if lis.markBroadcast(lis.broadcastConfirmations, conf.nodeHash) {
+				return
+			}
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[staker.myAddr], TxRequest{
+				Kind:     "ConfirmInvalid",
+				DedupKey: conf.nodeHash,
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return staker.contract.ConfirmInvalid(
+						ctx,
+						conf.deadlineTicks,
+						conf.challengeNodeData,
+						conf.branch,
+						conf.vmProtoStateHash,
+						conf.stakerAddresses,
+						conf.stakerProofs,
+						conf.stakerProofOffsets,
+					)
+				},
+			})
+		},
+		func() bool { return lis.alreadyBroadcast(lis.broadcastConfirmations, conf.nodeHash) },
+	)
 }
 
 func (lis *ValidatorChainListener) PrunableLeafs(params []pruneParams) {
-	for _, staker := range lis.stakers {
-		for _, prune := range params {
-			_, alreadySent := lis.broadcastLeafPrunes[prune.leafHash]
-			if alreadySent {
-				continue
-			}
-			lis.broadcastLeafPrunes[prune.leafHash] = true
-			pruneCopy := prune.Clone()
-			go func() {
-				staker.Lock()
-				staker.contract.PruneLeaf(
-					context.TODO(),
-					pruneCopy.ancestorHash,
-					pruneCopy.leafProof,
-					pruneCopy.ancProof,
-				)
-				staker.Unlock()
-			}()
+	for _, prune := range params {
+		if lis.alreadyBroadcast(lis.broadcastLeafPrunes, prune.leafHash) {
+			continue
 		}
-		break
+		pruneCopy := prune.Clone()
+		lis.submitIfLeader(
+			prune.leafHash,
+			func(staker *StakerListener) {
+				if lis.markBroadcast(lis.broadcastLeafPrunes, prune.leafHash) {
+					return
+				}
+				lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[staker.myAddr], TxRequest{
+					Kind:     "PruneLeaf",
+					DedupKey: prune.leafHash,
+					Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+						return staker.contract.PruneLeaf(
+							ctx,
+							pruneCopy.ancestorHash,
+							pruneCopy.leafProof,
+							pruneCopy.ancProof,
+						)
+					},
+				})
+			},
+			func() bool { return lis.alreadyBroadcast(lis.broadcastLeafPrunes, prune.leafHash) },
+		)
 	}
 }
 
 func (lis *ValidatorChainListener) MootableStakes(params []recoverStakeMootedParams) {
 	for _, staker := range lis.stakers {
 		for _, moot := range params {
-			go func() {
-				staker.Lock()
-				staker.contract.RecoverStakeMooted(
-					context.TODO(),
-					moot.ancestorHash,
-					moot.addr,
-					moot.lcProof,
-					moot.stProof,
-				)
-				staker.Unlock()
-			}()
+			moot := moot
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[staker.myAddr], TxRequest{
+				Kind:     "RecoverStakeMooted",
+				DedupKey: moot.ancestorHash,
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return staker.contract.RecoverStakeMooted(
+						ctx,
+						moot.ancestorHash,
+						moot.addr,
+						moot.lcProof,
+						moot.stProof,
+					)
+				},
+			})
 		}
 		break
 	}
@@ -328,15 +530,17 @@ func (lis *ValidatorChainListener) MootableStakes(params []recoverStakeMootedPar
 func (lis *ValidatorChainListener) OldStakes(params []recoverStakeOldParams) {
 	for _, staker := range lis.stakers {
 		for _, old := range params {
-			go func() {
-				staker.Lock()
-				staker.contract.RecoverStakeOld(
-					context.TODO(),
-					old.addr,
-					old.proof,
-				)
-				staker.Unlock()
-			}()
+			old := old
+			lis.txmgr.Enqueue(lis.ctx, lis.stakerAuths[staker.myAddr], TxRequest{
+				Kind: "RecoverStakeOld",
+				Send: func(ctx context.Context, _ *bind.TransactOpts) (*types.Transaction, error) {
+					return staker.contract.RecoverStakeOld(
+						ctx,
+						old.addr,
+						old.proof,
+					)
+				},
+			})
 		}
 		break
 	}
@@ -0,0 +1,74 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollup"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/structures"
+)
+
+func TestDivergingExecutionAssertionsChallengeExactlyOnce(t *testing.T) {
+	sc := NewSimChain(t, 2)
+	defer sc.Close()
+
+	asserter, challenger := sc.Stakers[0], sc.Stakers[1]
+	conflict := rollup.NewConflictNodeForTest(structures.InvalidExecutionChildType, 1)
+
+	sc.Listener.StartedChallenge(arbbridge.ChallengeStartedEvent{
+		Asserter:   asserter.From,
+		Challenger: challenger.From,
+	}, conflict, conflict)
+
+	// The conflict resolves into exactly one pair: the asserter's
+	// defendExecution and the challenger's challengeExecution. Both are
+	// dispatched through TxManager workers, so wait for their sends to
+	// land rather than checking the nonce delta immediately.
+	if got := sc.awaitSubmittedTxCount(2, time.Second); got != 2 {
+		t.Fatalf("expected exactly one defendExecution/challengeExecution pair (2 txs), got %d", got)
+	}
+}
+
+func TestStakeMovedIntoConflictTriggersChallenge(t *testing.T) {
+	sc := NewSimChain(t, 2)
+	defer sc.Close()
+
+	mover := sc.Stakers[1]
+	sc.InjectEvent(arbbridge.StakeMovedEvent{Staker: mover.From})
+	sc.Commit()
+
+	if got := sc.submittedTxCount(); got != 1 {
+		t.Fatalf("expected challengeStakerIfPossible to submit exactly one challenge, got %d", got)
+	}
+}
+
+func TestPrunableLeafsDedupesDuplicateHashes(t *testing.T) {
+	sc := NewSimChain(t, 1)
+	defer sc.Close()
+
+	leafHash := [32]byte{9, 9, 9}
+	sc.Listener.PrunableLeafs(rollup.NewPruneParamsForTest(leafHash, 3))
+
+	// PruneLeaf is dispatched through a TxManager worker, so wait for the
+	// send to land rather than checking the nonce delta immediately.
+	if got := sc.awaitSubmittedTxCount(1, time.Second); got != 1 {
+		t.Fatalf("expected a single PruneLeaf call for duplicate leaf hashes, got %d", got)
+	}
+}
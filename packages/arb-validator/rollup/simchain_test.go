@@ -0,0 +1,252 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup_test
+
+import (
+	"context"
+	"math/big"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/arb"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/arbbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-validator/rollup"
+)
+
+const simBackendGasLimit = 8_000_000
+
+// SimChain deploys the rollup contracts to a fresh SimulatedBackend, wires a
+// rollup.ValidatorChainListener with one staker per account, and exposes the
+// pieces a test needs to drive and observe it.
+type SimChain struct {
+	t        *testing.T
+	Backend  *backends.SimulatedBackend
+	Chain    *rollup.ChainObserver
+	Listener *rollup.ValidatorChainListener
+	Stakers  []*bind.TransactOpts
+
+	baselineGoroutines int
+	baselineNonces     map[common.Address]uint64
+}
+
+// NewSimChain funds numStakers accounts, deploys the rollup contracts, and
+// registers a staker per account with a fresh ValidatorChainListener using
+// the no-op elector (every listener submits immediately, as if leader
+// election weren't in play).
+func NewSimChain(t *testing.T, numStakers int) *SimChain {
+	t.Helper()
+	return newSimChain(t, numStakers, rollup.NoopElector{})
+}
+
+// NewSimChainWithElector is NewSimChain but wires the listener with elector
+// instead of the no-op default, for tests that need to control which staker
+// is elected leader.
+func NewSimChainWithElector(t *testing.T, numStakers int, elector rollup.LeaderElector) *SimChain {
+	t.Helper()
+	return newSimChain(t, numStakers, elector)
+}
+
+func newSimChain(t *testing.T, numStakers int, elector rollup.LeaderElector) *SimChain {
+	t.Helper()
+
+	alloc := core.GenesisAlloc{}
+	opts := make([]*bind.TransactOpts, 0, numStakers)
+	for i := 0; i < numStakers; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("rollup_test: failed to generate staker key: %v", err)
+		}
+		auth := bind.NewKeyedTransactor(key)
+		alloc[auth.From] = core.GenesisAccount{Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)}
+		opts = append(opts, auth)
+	}
+	backend := backends.NewSimulatedBackend(alloc, simBackendGasLimit)
+
+	rollupAddr, deployTx, _, err := arb.DeployRollup(opts[0], backend)
+	if err != nil {
+		t.Fatalf("rollup_test: failed to deploy rollup: %v", err)
+	}
+	backend.Commit()
+	if _, err := bind.WaitDeployed(context.Background(), backend, deployTx); err != nil {
+		t.Fatalf("rollup_test: rollup deploy never mined: %v", err)
+	}
+
+	chain := rollup.NewChainObserver(rollupAddr)
+	txmgr := rollup.NewTxManager(backend, 2*time.Second, 3)
+	listener := rollup.NewValidatorChainListener(context.Background(), chain, elector, txmgr)
+
+	sc := &SimChain{
+		t:                  t,
+		Backend:            backend,
+		Chain:              chain,
+		Listener:           listener,
+		Stakers:            opts,
+		baselineGoroutines: runtime.NumGoroutine(),
+	}
+	preStake := sc.nonces()
+	for _, auth := range opts {
+		if err := listener.AddStaker(backend, auth); err != nil {
+			t.Fatalf("rollup_test: failed to add staker %s: %v", auth.From.Hex(), err)
+		}
+	}
+	// AddStaker only enqueues each PlaceStake on the TxManager, so wait for
+	// those sends to land before taking the baseline tx counts start from.
+	deadline := time.Now().Add(time.Second)
+	for {
+		sc.Commit()
+		staked := true
+		for addr, nonce := range sc.nonces() {
+			if nonce <= preStake[addr] {
+				staked = false
+			}
+		}
+		if staked || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	sc.baselineNonces = sc.nonces()
+	return sc
+}
+
+func (sc *SimChain) nonces() map[common.Address]uint64 {
+	nonces := make(map[common.Address]uint64, len(sc.Stakers))
+	for _, auth := range sc.Stakers {
+		nonce, err := sc.Backend.PendingNonceAt(context.Background(), auth.From)
+		if err != nil {
+			sc.t.Fatalf("rollup_test: failed to read nonce for %s: %v", auth.From.Hex(), err)
+		}
+		nonces[auth.From] = nonce
+	}
+	return nonces
+}
+
+// submittedTxCount returns how many transactions the harness's stakers have
+// sent since NewSimChain finished registering them, inferred from nonce
+// deltas rather than instrumenting the contract binding directly.
+func (sc *SimChain) submittedTxCount() int {
+	total := 0
+	for addr, nonce := range sc.nonces() {
+		total += int(nonce - sc.baselineNonces[addr])
+	}
+	return total
+}
+
+// submittedTxCountFor returns how many transactions addr has sent since
+// NewSimChain finished registering it, inferred the same way as
+// submittedTxCount.
+func (sc *SimChain) submittedTxCountFor(addr common.Address) int {
+	return int(sc.nonces()[addr] - sc.baselineNonces[addr])
+}
+
+// awaitSubmittedTxCountFor polls submittedTxCountFor(addr), committing a
+// block each attempt, until it reaches at least want or timeout elapses.
+func (sc *SimChain) awaitSubmittedTxCountFor(addr common.Address, want int, timeout time.Duration) int {
+	sc.t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		sc.Commit()
+		if got := sc.submittedTxCountFor(addr); got >= want {
+			return got
+		}
+		if time.Now().After(deadline) {
+			return sc.submittedTxCountFor(addr)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// awaitSubmittedTxCount polls submittedTxCount, committing a block each
+// attempt, until it reaches at least want or timeout elapses. Listener
+// callbacks that dispatch through a goroutine or a TxManager worker return
+// before their send has necessarily happened, so a test driving one of those
+// callbacks must wait for the nonce delta rather than checking immediately.
+func (sc *SimChain) awaitSubmittedTxCount(want int, timeout time.Duration) int {
+	sc.t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		sc.Commit()
+		if got := sc.submittedTxCount(); got >= want {
+			return got
+		}
+		if time.Now().After(deadline) {
+			return sc.submittedTxCount()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Commit mines a block on the simulated backend.
+func (sc *SimChain) Commit() {
+	sc.Backend.Commit()
+}
+
+// AdvanceBlocks mines n empty blocks, e.g. to cross a challenge deadline.
+func (sc *SimChain) AdvanceBlocks(n int) {
+	for i := 0; i < n; i++ {
+		sc.Backend.Commit()
+	}
+}
+
+// InjectEvent delivers ev to the listener as if it had just been observed
+// on-chain, so a test can drive a specific ChainListener callback without
+// waiting on a real event subscription.
+func (sc *SimChain) InjectEvent(ev arbbridge.Event) {
+	switch e := ev.(type) {
+	case arbbridge.StakeCreatedEvent:
+		sc.Listener.StakeCreated(e)
+	case arbbridge.StakeRefundedEvent:
+		sc.Listener.StakeRemoved(e)
+	case arbbridge.StakeMovedEvent:
+		sc.Listener.StakeMoved(e)
+	case arbbridge.ChallengeCompletedEvent:
+		sc.Listener.CompletedChallenge(e)
+	case arbbridge.ConfirmedEvent:
+		sc.Listener.ConfirmedNode(e)
+	case arbbridge.PrunedEvent:
+		sc.Listener.PrunedLeaf(e)
+	default:
+		sc.t.Fatalf("rollup_test: unhandled event type %T", ev)
+	}
+}
+
+// Close cancels the listener's context and releases the harness, failing
+// the test if any goroutine the listener launched is still running
+// afterwards.
+func (sc *SimChain) Close() {
+	sc.t.Helper()
+	sc.Listener.Close()
+	sc.Backend.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if runtime.NumGoroutine() <= sc.baselineGoroutines {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > sc.baselineGoroutines {
+		sc.t.Errorf("rollup_test: %d listener goroutine(s) still running after Close", n-sc.baselineGoroutines)
+	}
+}
@@ -0,0 +1,247 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxClient is the subset of ethclient.Client that TxManager needs to track
+// nonces and bump gas on a stalled send.
+type TxClient interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// TxRequest is one contract call a ValidatorChainListener wants sent on
+// behalf of a staker. DedupKey identifies the on-chain effect the send is
+// for (e.g. a node hash); TxManager does not interpret it, callers use it to
+// correlate a TxRequest with their own broadcast-tracking state.
+type TxRequest struct {
+	Kind     string
+	DedupKey [32]byte
+	Send     func(ctx context.Context, auth *bind.TransactOpts) (*types.Transaction, error)
+}
+
+// TxFuture is resolved once a TxRequest has either landed on chain or failed
+// after exhausting retries.
+type TxFuture struct {
+	done chan struct{}
+	tx   *types.Transaction
+	err  error
+}
+
+func newTxFuture() *TxFuture {
+	return &TxFuture{done: make(chan struct{})}
+}
+
+func (f *TxFuture) complete(tx *types.Transaction, err error) {
+	f.tx, f.err = tx, err
+	close(f.done)
+}
+
+// Wait blocks until the request completes or ctx is done.
+func (f *TxFuture) Wait(ctx context.Context) (*types.Transaction, error) {
+	select {
+	case <-f.done:
+		return f.tx, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// TxManager serializes the transactions a single address sends, so that
+// ValidatorChainListener never needs to call a contract binding directly
+// while holding a staker's lock. Each staker gets its own worker goroutine
+// and its own nonce sequence; workers for different stakers run
+// concurrently. A send that doesn't land within sendTimeout is resubmitted
+// with a bumped gas tip, standard EIP-1559 replacement-transaction style.
+type TxManager struct {
+	client      TxClient
+	sendTimeout time.Duration
+	gasBumpBps  int64 // basis points (e.g. 1250 == 12.5%) added per retry
+	maxRetries  int
+
+	mu      sync.Mutex
+	workers map[common.Address]*stakerWorker
+}
+
+// NewTxManager builds a TxManager that gives each send up to sendTimeout to
+// be mined before bumping gas and resubmitting, retrying up to maxRetries
+// times.
+func NewTxManager(client TxClient, sendTimeout time.Duration, maxRetries int) *TxManager {
+	return &TxManager{
+		client:      client,
+		sendTimeout: sendTimeout,
+		gasBumpBps:  1250,
+		maxRetries:  maxRetries,
+		workers:     make(map[common.Address]*stakerWorker),
+	}
+}
+
+// Enqueue submits req to be sent by auth, returning a future the caller can
+// await. Requests for the same staker are sent strictly in order.
+func (m *TxManager) Enqueue(ctx context.Context, auth *bind.TransactOpts, req TxRequest) *TxFuture {
+	future := newTxFuture()
+	w := m.workerFor(auth)
+	w.submit(ctx, req, future)
+	return future
+}
+
+func (m *TxManager) workerFor(auth *bind.TransactOpts) *stakerWorker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.workers[auth.From]
+	if !ok {
+		w = &stakerWorker{mgr: m, auth: auth, queue: make(chan workItem, 16)}
+		m.workers[auth.From] = w
+		go w.run()
+	}
+	return w
+}
+
+// Close stops accepting new work. Queued-but-unsent requests whose Enqueue
+// context is done are completed with that context's error, without being
+// sent, when their worker reaches them; already in-flight sends are left to
+// finish or time out.
+func (m *TxManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.workers {
+		close(w.queue)
+	}
+}
+
+type workItem struct {
+	ctx    context.Context
+	req    TxRequest
+	future *TxFuture
+}
+
+type stakerWorker struct {
+	mgr   *TxManager
+	auth  *bind.TransactOpts
+	queue chan workItem
+}
+
+func (w *stakerWorker) submit(ctx context.Context, req TxRequest, future *TxFuture) {
+	defer func() {
+		// The queue is closed from Close after the listener's context is
+		// cancelled; a send on a closed channel would panic.
+		if r := recover(); r != nil {
+			future.complete(nil, context.Canceled)
+		}
+	}()
+	w.queue <- workItem{ctx: ctx, req: req, future: future}
+}
+
+func (w *stakerWorker) run() {
+	for item := range w.queue {
+		if err := item.ctx.Err(); err != nil {
+			item.future.complete(nil, err)
+			continue
+		}
+		tx, err := w.send(item.ctx, item.req)
+		item.future.complete(tx, err)
+	}
+}
+
+// send submits req, bumping the gas tip and resubmitting with the same
+// nonce (a replacement transaction) each time sendTimeout elapses without a
+// receipt, up to maxRetries times.
+func (w *stakerWorker) send(ctx context.Context, req TxRequest) (*types.Transaction, error) {
+	nonce, err := w.mgr.client.PendingNonceAt(ctx, w.auth.From)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := w.mgr.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		// Fall back to a legacy gas price suggestion on chains/backends
+		// that don't support the fee-history RPCs EIP-1559 needs.
+		tip, err = w.mgr.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var lastTx *types.Transaction
+	var lastErr error
+	for attempt := 0; attempt <= w.mgr.maxRetries; attempt++ {
+		opts := *w.auth
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		opts.GasTipCap = bumpedGasTip(tip, w.mgr.gasBumpBps, attempt)
+		opts.Context = ctx
+
+		tx, sendErr := req.Send(ctx, &opts)
+		if sendErr != nil {
+			lastErr = sendErr
+			continue
+		}
+		lastTx, lastErr = tx, nil
+
+		receiptCtx, cancel := context.WithTimeout(ctx, w.mgr.sendTimeout)
+		receipt, waitErr := waitMined(receiptCtx, w.mgr.client, tx.Hash())
+		cancel()
+		if waitErr == nil && receipt != nil {
+			return tx, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = waitErr
+		// Timed out waiting for this attempt; loop around and resubmit a
+		// replacement transaction with a higher tip at the same nonce.
+	}
+	return lastTx, lastErr
+}
+
+// bumpedGasTip increases tip by bumpBps basis points per attempt, compounding
+// so repeated replacements keep clearing the minimum-10%-bump rule most
+// clients enforce for replacement transactions.
+func bumpedGasTip(tip *big.Int, bumpBps int64, attempt int) *big.Int {
+	bumped := new(big.Int).Set(tip)
+	for i := 0; i < attempt; i++ {
+		bumped = new(big.Int).Div(new(big.Int).Mul(bumped, big.NewInt(10000+bumpBps)), big.NewInt(10000))
+	}
+	return bumped
+}
+
+// waitMined polls for txHash's receipt until ctx is done.
+func waitMined(ctx context.Context, client TxClient, txHash common.Hash) (*types.Receipt, error) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		if err == nil && receipt != nil {
+			return receipt, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,40 @@
+/*
+* Copyright 2020, Offchain Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rollup
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBumpedGasTip(t *testing.T) {
+	tip := big.NewInt(1000)
+
+	if got := bumpedGasTip(tip, 1250, 0); got.Cmp(tip) != 0 {
+		t.Fatalf("attempt 0 should not bump the tip, got %s", got)
+	}
+
+	bumped := bumpedGasTip(tip, 1250, 1)
+	if bumped.Cmp(tip) <= 0 {
+		t.Fatalf("expected attempt 1 to bump the tip above %s, got %s", tip, bumped)
+	}
+
+	twiceBumped := bumpedGasTip(tip, 1250, 2)
+	if twiceBumped.Cmp(bumped) <= 0 {
+		t.Fatalf("expected attempt 2 to bump further than attempt 1 (%s), got %s", bumped, twiceBumped)
+	}
+}